@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Transition describes a single edge out of a Status: what it goes to, the
+// event that triggers it, an optional guard condition, and an optional
+// action performed on the way. It unmarshals from either a plain string
+// (the legacy `["Name"]` form, promoted to Transition{To: s}) or a full
+// JSON object.
+type Transition struct {
+	To     string `json:""`
+	Event  string `json:",omitempty"`
+	Guard  string `json:",omitempty"`
+	Action string `json:",omitempty"`
+}
+
+// UnmarshalJSON accepts either a bare string naming the target status or a
+// full transition object, so existing `NextStatus: ["Name"]` flows keep
+// working unchanged.
+func (t *Transition) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		t.To = name
+		return nil
+	}
+
+	type transitionAlias Transition
+	var alias transitionAlias
+	if err := json.Unmarshal(data, &alias); err != nil {
+		return err
+	}
+	*t = Transition(alias)
+	return nil
+}
+
+// Status represents a state in the flow and its outgoing transitions.
+// It is used to define the nodes and edges of the status flow graph.
+type Status struct {
+	Name       string       // The name of the current status
+	NextStatus []Transition // The transitions that can be taken from the current status
+	Terminal   bool         `json:",omitempty"` // Marks a status as an intentional dead-end
+}
+
+// parseFlows parses flow JSON that is either a single flow (a `[]Status`
+// array) or multiple named flows (a `map[string][]Status` object), always
+// returning a map keyed by flow name. singleName is used as the key when
+// the input is a single flow, so callers can treat both shapes uniformly.
+func parseFlows(raw []byte, singleName string) (map[string][]Status, error) {
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		var flows map[string][]Status
+		if err := json.Unmarshal(raw, &flows); err != nil {
+			return nil, err
+		}
+		return flows, nil
+	}
+
+	var statuses []Status
+	if err := json.Unmarshal(raw, &statuses); err != nil {
+		return nil, err
+	}
+	return map[string][]Status{singleName: statuses}, nil
+}
+
+// Issue describes a single problem found by Validate.
+type Issue struct {
+	Kind   string // "duplicate", "unreachable", "dead-end", or "undefined-next"
+	Status string
+	Message string
+}
+
+// Validate checks a flow for common modelling mistakes: states that are
+// never reached by any transition, dead-ends with no outgoing transitions
+// that aren't explicitly marked Terminal, transitions to states that don't
+// exist, and duplicate state names. Duplicate-name issues come first; the
+// other issues follow in the order the affected statuses were defined, so
+// output is stable.
+func Validate(statuses []Status) []Issue {
+	var issues []Issue
+
+	defined := make(map[string]bool, len(statuses))
+	seen := make(map[string]bool, len(statuses))
+	for _, status := range statuses {
+		if seen[status.Name] {
+			issues = append(issues, Issue{
+				Kind:    "duplicate",
+				Status:  status.Name,
+				Message: fmt.Sprintf("status %q is defined more than once", status.Name),
+			})
+		}
+		seen[status.Name] = true
+		defined[status.Name] = true
+	}
+
+	reachable := make(map[string]bool, len(statuses))
+	if len(statuses) > 0 {
+		reachable[statuses[0].Name] = true // the first status is the entry point
+	}
+	for _, status := range statuses {
+		for _, next := range status.NextStatus {
+			reachable[next.To] = true
+		}
+	}
+
+	for _, status := range statuses {
+		if !reachable[status.Name] {
+			issues = append(issues, Issue{
+				Kind:    "unreachable",
+				Status:  status.Name,
+				Message: fmt.Sprintf("status %q is never reached by any transition", status.Name),
+			})
+		}
+
+		if len(status.NextStatus) == 0 && !status.Terminal {
+			issues = append(issues, Issue{
+				Kind:    "dead-end",
+				Status:  status.Name,
+				Message: fmt.Sprintf("status %q has no outgoing transitions and is not marked Terminal", status.Name),
+			})
+		}
+
+		for _, next := range status.NextStatus {
+			if !defined[next.To] {
+				issues = append(issues, Issue{
+					Kind:    "undefined-next",
+					Status:  status.Name,
+					Message: fmt.Sprintf("status %q transitions to undefined status %q", status.Name, next.To),
+				})
+			}
+		}
+	}
+
+	return issues
+}
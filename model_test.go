@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func hasIssue(issues []Issue, kind, status string) bool {
+	for _, issue := range issues {
+		if issue.Kind == kind && issue.Status == status {
+			return true
+		}
+	}
+	return false
+}
+
+func TestValidateDuplicate(t *testing.T) {
+	statuses := []Status{
+		{Name: "A", NextStatus: []Transition{{To: "A"}}},
+		{Name: "A", NextStatus: []Transition{}},
+	}
+
+	issues := Validate(statuses)
+	if !hasIssue(issues, "duplicate", "A") {
+		t.Errorf("expected a duplicate issue for %q, got %+v", "A", issues)
+	}
+}
+
+func TestValidateUnreachable(t *testing.T) {
+	statuses := []Status{
+		{Name: "Start", NextStatus: []Transition{{To: "Start"}}},
+		{Name: "Orphan", NextStatus: []Transition{}, Terminal: true},
+	}
+
+	issues := Validate(statuses)
+	if !hasIssue(issues, "unreachable", "Orphan") {
+		t.Errorf("expected an unreachable issue for %q, got %+v", "Orphan", issues)
+	}
+}
+
+func TestValidateDeadEnd(t *testing.T) {
+	statuses := []Status{
+		{Name: "Start", NextStatus: []Transition{{To: "End"}}},
+		{Name: "End", NextStatus: []Transition{}},
+	}
+
+	issues := Validate(statuses)
+	if !hasIssue(issues, "dead-end", "End") {
+		t.Errorf("expected a dead-end issue for %q, got %+v", "End", issues)
+	}
+}
+
+func TestValidateDeadEndAllowedWhenTerminal(t *testing.T) {
+	statuses := []Status{
+		{Name: "Start", NextStatus: []Transition{{To: "End"}}},
+		{Name: "End", NextStatus: []Transition{}, Terminal: true},
+	}
+
+	issues := Validate(statuses)
+	if hasIssue(issues, "dead-end", "End") {
+		t.Errorf("did not expect a dead-end issue for a Terminal status, got %+v", issues)
+	}
+}
+
+func TestValidateUndefinedNext(t *testing.T) {
+	statuses := []Status{
+		{Name: "Start", NextStatus: []Transition{{To: "Nowhere"}}},
+	}
+
+	issues := Validate(statuses)
+	if !hasIssue(issues, "undefined-next", "Start") {
+		t.Errorf("expected an undefined-next issue for %q, got %+v", "Start", issues)
+	}
+}
+
+func TestValidateCleanFlowHasNoIssues(t *testing.T) {
+	statuses := []Status{
+		{Name: "Start", NextStatus: []Transition{{To: "End", Event: "finish"}}},
+		{Name: "End", NextStatus: []Transition{}, Terminal: true},
+	}
+
+	if issues := Validate(statuses); len(issues) != 0 {
+		t.Errorf("expected no issues for a well-formed flow, got %+v", issues)
+	}
+}
+
+func TestTransitionUnmarshalJSONString(t *testing.T) {
+	var transition Transition
+	if err := json.Unmarshal([]byte(`"Completed"`), &transition); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := Transition{To: "Completed"}
+	if transition != want {
+		t.Errorf("got %+v, want %+v", transition, want)
+	}
+}
+
+func TestTransitionUnmarshalJSONObject(t *testing.T) {
+	var transition Transition
+	raw := `{"To":"Completed","Event":"finish","Guard":"isValid","Action":"notify"}`
+	if err := json.Unmarshal([]byte(raw), &transition); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := Transition{To: "Completed", Event: "finish", Guard: "isValid", Action: "notify"}
+	if transition != want {
+		t.Errorf("got %+v, want %+v", transition, want)
+	}
+}
+
+func TestStatusNextStatusAcceptsMixedForms(t *testing.T) {
+	var status Status
+	raw := `{"Name":"Start","NextStatus":["Completed",{"To":"Failed","Event":"error"}]}`
+	if err := json.Unmarshal([]byte(raw), &status); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(status.NextStatus) != 2 {
+		t.Fatalf("expected 2 transitions, got %d", len(status.NextStatus))
+	}
+	if status.NextStatus[0] != (Transition{To: "Completed"}) {
+		t.Errorf("got %+v, want Transition{To: \"Completed\"}", status.NextStatus[0])
+	}
+	if status.NextStatus[1] != (Transition{To: "Failed", Event: "error"}) {
+		t.Errorf("got %+v, want Transition{To: \"Failed\", Event: \"error\"}", status.NextStatus[1])
+	}
+}
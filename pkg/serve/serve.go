@@ -0,0 +1,103 @@
+// Package serve renders a DOT graph as a pannable, zoomable SVG and serves
+// it over HTTP so the flow can be inspected in a browser without writing a
+// PNG to disk first (handy over an SSH port-forward).
+package serve
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"os/exec"
+	"strconv"
+)
+
+// Start renders dot as SVG via Graphviz and blocks serving it on
+// host:port until the process is interrupted or an unrecoverable error
+// occurs. Callers should default host to "127.0.0.1" so the diagram isn't
+// exposed beyond localhost (e.g. to the rest of a shared network or VPC);
+// an explicit "0.0.0.0" opts back into listening on all interfaces.
+func Start(dot string, host string, port int) error {
+	svg, err := renderSVG(dot)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := pageTemplate.Execute(w, pageData{SVG: template.HTML(svg)}); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+
+	return http.ListenAndServe(net.JoinHostPort(host, strconv.Itoa(port)), mux)
+}
+
+// renderSVG shells out to Graphviz's "dot" to turn DOT source into inline SVG.
+func renderSVG(dot string) (string, error) {
+	cmd := exec.Command("dot", "-Tsvg")
+	cmd.Stdin = bytes.NewBufferString(dot)
+
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("error rendering SVG: %v", err)
+	}
+	return out.String(), nil
+}
+
+type pageData struct {
+	SVG template.HTML
+}
+
+var pageTemplate = template.Must(template.New("page").Parse(pageHTML))
+
+const pageHTML = `<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<title>Status Flow</title>
+	<script src="https://cdn.jsdelivr.net/npm/svg-pan-zoom@3.6.1/dist/svg-pan-zoom.min.js"></script>
+	<style>
+		html, body { margin: 0; height: 100%; overflow: hidden; font-family: Helvetica, Arial, sans-serif; }
+		svg { width: 100%; height: 100%; }
+		.node.is-active polygon, .node.is-active ellipse { stroke: #ff8c00; stroke-width: 2; }
+		.edge.is-active path { stroke: #ff8c00; stroke-width: 2; }
+		.edge.is-active polygon { stroke: #ff8c00; fill: #ff8c00; }
+	</style>
+</head>
+<body>
+	{{.SVG}}
+	<script>
+		var svg = document.querySelector("svg");
+		svgPanZoom(svg, { zoomEnabled: true, controlIconsEnabled: true, fit: true, center: true });
+
+		// Highlight the hovered state and its outgoing transitions. Graphviz
+		// names edge <g> titles "A->B", so we match on the node's own title.
+		document.querySelectorAll(".node").forEach(function (node) {
+			var title = node.querySelector("title");
+			if (!title) return;
+			var name = title.textContent;
+
+			node.addEventListener("mouseenter", function () {
+				node.classList.add("is-active");
+				document.querySelectorAll(".edge").forEach(function (edge) {
+					var edgeTitle = edge.querySelector("title");
+					if (edgeTitle && edgeTitle.textContent.indexOf(name + "->") === 0) {
+						edge.classList.add("is-active");
+					}
+				});
+			});
+			node.addEventListener("mouseleave", function () {
+				node.classList.remove("is-active");
+				document.querySelectorAll(".edge.is-active").forEach(function (edge) {
+					edge.classList.remove("is-active");
+				});
+			});
+		});
+	</script>
+</body>
+</html>
+`
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+)
+
+// validFormats are the output formats this tool understands. "dot" skips
+// invoking Graphviz entirely and leaves just the DOT source on disk.
+var validFormats = map[string]bool{
+	"png":  true,
+	"svg":  true,
+	"pdf":  true,
+	"json": true,
+	"dot":  true,
+}
+
+// GenerateGraphImage renders a DOT file into the given Graphviz output format
+// (png, svg, pdf, or json/xdot-JSON) using the "dot" command.
+func GenerateGraphImage(dotFile string, outputFile string, format string) error {
+	cmd := exec.Command("dot", "-T"+format, dotFile, "-o", outputFile)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error generating %s graph: %v", format, err)  // Return an error if the command fails
+	}
+	return nil
+}
+
+// sanitizeFlowName rejects flow names that can't be used as-is as a
+// filename within outputPath. In multi-flow mode the name comes straight
+// from the input JSON map, which chunk0-2 allows fetching from an arbitrary
+// URL, so a name containing a path separator (e.g. "../../etc/cron.d/x")
+// must not be allowed to escape outputPath via filepath.Join.
+func sanitizeFlowName(name string) error {
+	if name == "" {
+		return fmt.Errorf("flow name must not be empty")
+	}
+	if filepath.Base(name) != name {
+		return fmt.Errorf("flow name %q must not contain path separators", name)
+	}
+	return nil
+}
+
+// renderFlow validates, writes the DOT source for, and (unless format is
+// "dot") renders a single named flow into outputPath/<name>.<format>. It
+// returns the path of the file a reader should open (the rendered image, or
+// the .dot file itself when format is "dot") and whether rendering succeeded.
+func renderFlow(name string, statuses []Status, outputPath, direction, format string, allowInvalid bool) (string, bool) {
+	if err := sanitizeFlowName(name); err != nil {
+		fmt.Printf("Skipping flow %q: %v\n", name, err)
+		return "", false
+	}
+
+	if issues := Validate(statuses); len(issues) > 0 {
+		for _, issue := range issues {
+			fmt.Printf("Validation issue for %q (%s): %s\n", name, issue.Kind, issue.Message)
+		}
+		if !allowInvalid {
+			fmt.Printf("Refusing to render %q; pass --allow-invalid to render anyway.\n", name)
+			return "", false
+		}
+	}
+
+	dotContent := GenerateDOT(statuses, direction)
+	dotFilename := filepath.Join(outputPath, name+".dot")
+	if err := WriteDOTToFile(dotContent, dotFilename); err != nil {
+		fmt.Printf("Error writing DOT file for %q: %v\n", name, err)
+		return "", false
+	}
+
+	if format == "dot" {
+		return dotFilename, true
+	}
+
+	outputFilename := filepath.Join(outputPath, name+"."+format)
+	if err := GenerateGraphImage(dotFilename, outputFilename, format); err != nil {
+		fmt.Printf("Error generating graph for %q: %v\n", name, err)
+		return "", false
+	}
+	return outputFilename, true
+}
+
+// indexEntry is a single row of the index page. Flow names come straight
+// from the input JSON's map keys (chunk0-2 lets that JSON be fetched from a
+// URL), so indexTemplate relies on html/template's auto-escaping rather than
+// string concatenation to keep an untrusted name from breaking out of the
+// page's markup.
+type indexEntry struct {
+	Name string
+	Href string
+}
+
+var indexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html>
+<head><title>State Flows</title></head>
+<body>
+<ul>
+{{- range . }}
+  <li><a href="{{.Href}}">{{.Name}}</a></li>
+{{- end }}
+</ul>
+</body>
+</html>
+`))
+
+// writeIndexHTML writes outputPath/index.html linking to every rendered
+// file, keyed by flow name, so a whole service's state machines can be
+// browsed from one page.
+func writeIndexHTML(outputPath string, rendered map[string]string) error {
+	names := make([]string, 0, len(rendered))
+	for name := range rendered {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	entries := make([]indexEntry, 0, len(names))
+	for _, name := range names {
+		entries = append(entries, indexEntry{Name: name, Href: filepath.Base(rendered[name])})
+	}
+
+	file, err := os.Create(filepath.Join(outputPath, "index.html"))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return indexTemplate.Execute(file, entries)
+}
+
+// sortedFlowNames returns a flow map's keys in a stable order, so multi-flow
+// batches render in the same order on every run.
+func sortedFlowNames(flows map[string][]Status) []string {
+	names := make([]string, 0, len(flows))
+	for name := range flows {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
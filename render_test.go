@@ -0,0 +1,95 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseFlowsSingle(t *testing.T) {
+	raw := `[{"Name":"A","NextStatus":["B"]},{"Name":"B","NextStatus":[]}]`
+	flows, err := parseFlows([]byte(raw), "default")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	statuses, ok := flows["default"]
+	if !ok || len(flows) != 1 {
+		t.Fatalf("expected a single flow keyed %q, got %+v", "default", flows)
+	}
+	if len(statuses) != 2 {
+		t.Errorf("expected 2 statuses, got %d", len(statuses))
+	}
+}
+
+func TestParseFlowsMulti(t *testing.T) {
+	raw := `{"Order":[{"Name":"New","NextStatus":[]}],"Shipment":[{"Name":"Packed","NextStatus":[]}]}`
+	flows, err := parseFlows([]byte(raw), "default")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(flows) != 2 {
+		t.Fatalf("expected 2 flows, got %+v", flows)
+	}
+	if _, ok := flows["Order"]; !ok {
+		t.Errorf("expected a flow named %q", "Order")
+	}
+	if _, ok := flows["Shipment"]; !ok {
+		t.Errorf("expected a flow named %q", "Shipment")
+	}
+}
+
+func TestSanitizeFlowNameRejectsPathSeparators(t *testing.T) {
+	for _, name := range []string{"../../etc/cron.d/x", "a/b", ""} {
+		if err := sanitizeFlowName(name); err == nil {
+			t.Errorf("expected sanitizeFlowName(%q) to reject the name", name)
+		}
+	}
+
+	if err := sanitizeFlowName("Order"); err != nil {
+		t.Errorf("expected sanitizeFlowName(\"Order\") to accept a plain name, got %v", err)
+	}
+}
+
+func TestRenderFlowRejectsPathEscapingName(t *testing.T) {
+	dir := t.TempDir()
+	statuses := []Status{{Name: "A", NextStatus: []Transition{}, Terminal: true}}
+
+	if _, ok := renderFlow("../../etc/cron.d/x", statuses, dir, "LR", "dot", true); ok {
+		t.Fatal("expected renderFlow to reject a flow name containing path separators")
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected no files written to %q, got %v", dir, entries)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "..", "..", "etc", "cron.d", "x.dot")); err == nil {
+		t.Error("renderFlow escaped outputPath")
+	}
+}
+
+func TestWriteIndexHTMLEscapesFlowNames(t *testing.T) {
+	dir := t.TempDir()
+	malicious := `<img src=x onerror=alert(1)>`
+
+	if err := writeIndexHTML(dir, map[string]string{malicious: filepath.Join(dir, "a.svg")}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	contents, err := os.ReadFile(filepath.Join(dir, "index.html"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if strings.Contains(string(contents), malicious) {
+		t.Errorf("expected flow name to be HTML-escaped, got:\n%s", contents)
+	}
+	if !strings.Contains(string(contents), "&lt;img src=x onerror=alert(1)&gt;") {
+		t.Errorf("expected an HTML-escaped flow name in the output, got:\n%s", contents)
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// readSource loads the raw flow JSON from "-" (stdin), a filesystem path, or
+// an http(s):// URL, mirroring the `docker import` convention so the tool
+// can be pointed at a spec published by another service without shell
+// plumbing.
+func readSource(source string, timeout time.Duration) ([]byte, error) {
+	if source == "" || source == "-" {
+		return ioutil.ReadAll(os.Stdin)
+	}
+
+	if u, err := url.Parse(source); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		fmt.Printf("Downloading from %s...\n", source)
+
+		client := &http.Client{Timeout: timeout}
+		resp, err := client.Get(source)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching %s: %v", source, err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("error fetching %s: unexpected status %s", source, resp.Status)
+		}
+
+		return ioutil.ReadAll(resp.Body)
+	}
+
+	return ioutil.ReadFile(source)
+}
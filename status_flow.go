@@ -1,53 +1,125 @@
 package main
 
 import (
-	"encoding/json"
 	"flag"
 	"fmt"
-	"io/ioutil"
 	"os"
-	"path/filepath"
-	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/shreifelagamy/state-machine-flow/pkg/serve"
 )
 
-// Status represents a state in the flow and its potential next statuses.
-// It is used to define the nodes and edges of the status flow graph.
-type Status struct {
-	Name       string      // The name of the current status
-	NextStatus []string    // A list of statuses that can be transitioned to from the current status
+// validDirections are the rankdir values Graphviz understands.
+var validDirections = map[string]bool{
+	"TB": true,
+	"BT": true,
+	"LR": true,
+	"RL": true,
 }
 
 // GenerateDOT creates a DOT representation of the statuses and their flows.
-// It takes a slice of Status objects and returns a string in DOT graph format.
-// The generated graph is left-to-right oriented with rounded blue boxes for nodes.
-func GenerateDOT(statuses []Status) string {
+// It takes a slice of Status objects plus a Graphviz rankdir (TB/BT/LR/RL)
+// and returns a string in DOT graph format.
+// The generated graph uses rounded blue boxes for nodes; terminal statuses
+// are drawn with a double border in dark red.
+func GenerateDOT(statuses []Status, direction string) string {
+	if !validDirections[direction] {
+		direction = "LR"
+	}
+
 	dot := "digraph G {\n"  // Start of the DOT graph definition
-	dot += "rankdir=LR;\n"  // Set the direction of the graph to left-to-right
+	dot += fmt.Sprintf("rankdir=%s;\n", direction)  // Set the direction of the graph
 	dot += "node [shape=box, style=rounded, color=blue, fontname=Helvetica];\n"  // Set node style to be more creative and visually appealing
 	visited := make(map[string]struct{})  // A map to keep track of which statuses have already been visited using an empty struct for efficiency
 
-	// Loop over each status and create the flow relationships.
+	// Declare every known status first so terminal states get their own style
+	// even though they're also referenced as a transition's target below.
 	for _, status := range statuses {
-		// Add the current status node if it hasn't been added yet
-		if _, ok := visited[status.Name]; !ok {
-			dot += fmt.Sprintf("  \"%s\";\n", status.Name)  // Add the status as a node in the graph
-			visited[status.Name] = struct{}{}
+		if status.Terminal {
+			dot += fmt.Sprintf("  \"%s\" [peripheries=2, color=darkred];\n", escapeDOT(status.Name))  // Terminal states get a double border and stand out in red
+		} else {
+			dot += fmt.Sprintf("  \"%s\";\n", escapeDOT(status.Name))  // Add the status as a node in the graph
 		}
-		// Loop over each of the next possible statuses
+		visited[status.Name] = struct{}{}
+	}
+
+	// Loop over each status and create the flow relationships.
+	for _, status := range statuses {
+		// Loop over each of the transitions out of the current status
 		for _, next := range status.NextStatus {
-			// Add the next status node if it hasn't been added yet
-			if _, ok := visited[next]; !ok {
-				dot += fmt.Sprintf("  \"%s\";\n", next)  // Add the next status as a node in the graph
-				visited[next] = struct{}{}
+			// Add the next status node if it hasn't been added yet (e.g. it's referenced but never defined)
+			if _, ok := visited[next.To]; !ok {
+				dot += fmt.Sprintf("  \"%s\";\n", escapeDOT(next.To))  // Add the next status as a node in the graph
+				visited[next.To] = struct{}{}
+			}
+			// Create an edge from the current status to the next status, labelled with its event/guard/action if any
+			if label := transitionLabel(next); label != "" {
+				dot += fmt.Sprintf("  \"%s\" -> \"%s\" [label=\"%s\"];\n", escapeDOT(status.Name), escapeDOT(next.To), label)
+			} else {
+				dot += fmt.Sprintf("  \"%s\" -> \"%s\";\n", escapeDOT(status.Name), escapeDOT(next.To))
 			}
-			// Create an edge from the current status to the next status
-			dot += fmt.Sprintf("  \"%s\" -> \"%s\";\n", status.Name, next)
 		}
 	}
 	dot += "}\n"  // End of the DOT graph definition
 	return dot
 }
 
+// escapeDOT makes s safe to interpolate inside a double-quoted DOT string:
+// it backslash-escapes backslashes and double quotes, writes newlines as the
+// DOT escape `\n`, and strips other control characters (which DOT doesn't
+// allow unescaped inside a string).
+// Every field that ends up inside a `"..."` in GenerateDOT must pass through
+// this first, since an attacker-controlled status name could otherwise
+// inject DOT attributes (e.g. a `URL=` attribute that Graphviz's SVG output
+// turns into a clickable link).
+func escapeDOT(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\\':
+			b.WriteString(`\\`)
+		case r == '"':
+			b.WriteString(`\"`)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r < 0x20:
+			// Drop other control characters rather than trying to escape them.
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// transitionLabel formats a Transition's event, guard, and action into a DOT
+// edge label of the form "event/guard\naction", omitting parts that aren't
+// set. Each field is escaped before being combined with the literal "/" and
+// "\n" separators so it can't inject its own DOT syntax.
+func transitionLabel(t Transition) string {
+	event := escapeDOT(t.Event)
+	guard := escapeDOT(t.Guard)
+	action := escapeDOT(t.Action)
+
+	var head string
+	switch {
+	case event != "" && guard != "":
+		head = event + "/" + guard
+	case event != "":
+		head = event
+	case guard != "":
+		head = "/" + guard
+	}
+
+	if action == "" {
+		return head
+	}
+	if head == "" {
+		return action
+	}
+	return head + "\\n" + action
+}
+
 // WriteDOTToFile writes the DOT content to a file.
 func WriteDOTToFile(dot string, filename string) error {
 	// Create a new file with the given filename
@@ -65,75 +137,118 @@ func WriteDOTToFile(dot string, filename string) error {
 	return nil
 }
 
-// GenerateGraphImage generates an image using Graphviz.
-func GenerateGraphImage(dotFile string, outputFile string) error {
-	// Use the "dot" command from Graphviz to generate a PNG image from the DOT file
-	cmd := exec.Command("dot", "-Tpng", dotFile, "-o", outputFile)
-	err := cmd.Run()
-	if err != nil {
-		return fmt.Errorf("error generating graph image: %v", err)  // Return an error if the command fails
-	}
-	return nil
-}
-
 func main() {
 	// Define command line flags
 	outputPath := flag.String("path", ".", "Output directory path")
-	outputName := flag.String("name", "status_flow", "Base name for output files (without extension)")
+	outputName := flag.String("name", "status_flow", "Base name for output files when the input is a single flow (without extension)")
 	useStaticData := flag.Bool("static", false, "Use static data for generating the image")
+	direction := flag.String("direction", "LR", "Graph direction: TB, BT, LR, or RL")
+	serveMode := flag.Bool("serve", false, "Serve the flow as an interactive SVG over HTTP instead of writing a file")
+	noServe := flag.Bool("no-serve", false, "Disable serve mode even when --serve is set (useful when scripting)")
+	port := flag.Int("port", 8080, "Port to listen on when --serve is set")
+	bind := flag.String("bind", "127.0.0.1", "Address to listen on when --serve is set (use 0.0.0.0 to allow connections from other hosts)")
+	input := flag.String("input", "", "Source to read the flow JSON from: '-' for stdin, a file path, or an http(s):// URL (defaults to the positional source argument, then stdin)")
+	timeout := flag.Duration("timeout", 30*time.Second, "Timeout when fetching --input from a URL")
+	allowInvalid := flag.Bool("allow-invalid", false, "Render a flow even if Validate reports issues")
+	format := flag.String("format", "png", "Output format: png, svg, pdf, json, or dot (dot skips rendering)")
+	index := flag.Bool("index", false, "With --format=svg, also write an index.html linking to every rendered flow")
 	flag.Parse()
 
+	source := *input
+	if source == "" {
+		source = flag.Arg(0)
+	}
+
+	if !validDirections[*direction] {
+		fmt.Printf("Invalid --direction %q: must be one of TB, BT, LR, RL\n", *direction)
+		return
+	}
+	if !validFormats[*format] {
+		fmt.Printf("Invalid --format %q: must be one of png, svg, pdf, json, dot\n", *format)
+		return
+	}
+
 	// Create output directory if it doesn't exist
 	if err := os.MkdirAll(*outputPath, 0755); err != nil {
 		fmt.Printf("Error creating output directory: %v\n", err)
 		return
 	}
 
-	var statuses []Status
+	var flows map[string][]Status
 
 	if *useStaticData {
 		// Use predefined static data
-		statuses = []Status{
-			{Name: "Start", NextStatus: []string{"In Progress"}},
-			{Name: "In Progress", NextStatus: []string{"Completed", "Failed"}},
-			{Name: "Completed", NextStatus: []string{}},
-			{Name: "Failed", NextStatus: []string{}},
+		flows = map[string][]Status{
+			*outputName: {
+				{Name: "Start", NextStatus: []Transition{{To: "In Progress", Event: "submit"}}},
+				{Name: "In Progress", NextStatus: []Transition{
+					{To: "Completed", Event: "finish"},
+					{To: "Failed", Event: "error"},
+				}},
+				{Name: "Completed", NextStatus: []Transition{}, Terminal: true},
+				{Name: "Failed", NextStatus: []Transition{}, Terminal: true},
+			},
 		}
 	} else {
-		// Read input JSON from stdin
-		input, err := ioutil.ReadAll(os.Stdin)
+		// Read the flow JSON from stdin, a file, or a URL
+		raw, err := readSource(source, *timeout)
 		if err != nil {
 			fmt.Printf("Error reading input: %v\n", err)
 			return
 		}
 
-		// Parse the input JSON into an array of statuses
-		err = json.Unmarshal(input, &statuses)
+		// The input is either a single flow ([]Status) or multiple named
+		// flows (map[string][]Status); parseFlows normalises both to a map.
+		flows, err = parseFlows(raw, *outputName)
 		if err != nil {
 			fmt.Printf("Error parsing input JSON: %v\n", err)
 			return
 		}
 	}
 
-	// Generate the DOT representation
-	dotContent := GenerateDOT(statuses)
-
-	// Create full file paths
-	dotFilename := filepath.Join(*outputPath, *outputName+".dot")
-	pngFilename := filepath.Join(*outputPath, *outputName+".png")
+	if *serveMode && !*noServe {
+		if len(flows) != 1 {
+			fmt.Println("Error: --serve only supports a single flow; pass a plain []Status input rather than a map of flows")
+			return
+		}
+		for _, statuses := range flows {
+			if issues := Validate(statuses); len(issues) > 0 {
+				for _, issue := range issues {
+					fmt.Printf("Validation issue (%s): %s\n", issue.Kind, issue.Message)
+				}
+				if !*allowInvalid {
+					fmt.Println("Refusing to serve an invalid flow; pass --allow-invalid to serve anyway.")
+					os.Exit(1)
+				}
+			}
 
-	// Write DOT content to a file
-	if err := WriteDOTToFile(dotContent, dotFilename); err != nil {
-		fmt.Printf("Error writing DOT file: %v\n", err)
+			fmt.Printf("Serving flow diagram at http://%s:%d\n", *bind, *port)
+			if err := serve.Start(GenerateDOT(statuses, *direction), *bind, *port); err != nil {
+				fmt.Printf("Error serving flow diagram: %v\n", err)
+			}
+		}
 		return
 	}
 
-	// Generate the status flow image using Graphviz
-	if err := GenerateGraphImage(dotFilename, pngFilename); err != nil {
-		fmt.Printf("Error generating graph image: %v\n", err)
-		return
+	rendered := make(map[string]string, len(flows))
+	allOK := true
+	for _, name := range sortedFlowNames(flows) {
+		path, ok := renderFlow(name, flows[name], *outputPath, *direction, *format, *allowInvalid)
+		if !ok {
+			allOK = false
+			continue
+		}
+		rendered[name] = path
+		fmt.Printf("Status flow image generated: %s\n", path)
 	}
 
-	// Inform the user that the image was successfully generated
-	fmt.Printf("Status flow image generated: %s\n", pngFilename)
+	if *index && *format == "svg" && len(rendered) > 0 {
+		if err := writeIndexHTML(*outputPath, rendered); err != nil {
+			fmt.Printf("Error writing index.html: %v\n", err)
+		}
+	}
+
+	if !allOK {
+		os.Exit(1)
+	}
 }
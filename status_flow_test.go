@@ -0,0 +1,43 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEscapeDOT(t *testing.T) {
+	cases := map[string]string{
+		`plain`:              `plain`,
+		`has "quotes"`:       `has \"quotes\"`,
+		`back\slash`:         `back\\slash`,
+		"control\x00char":    "controlchar",
+		"line\nbreak":        `line\nbreak`,
+	}
+
+	for in, want := range cases {
+		if got := escapeDOT(in); got != want {
+			t.Errorf("escapeDOT(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestGenerateDOTEscapesInjectedAttributes(t *testing.T) {
+	malicious := `A", URL="javascript:alert(1)", color="red`
+	statuses := []Status{
+		{Name: malicious, NextStatus: []Transition{{To: "B", Event: `go"] ; node [fake`}}},
+		{Name: "B", NextStatus: []Transition{}, Terminal: true},
+	}
+
+	dot := GenerateDOT(statuses, "LR")
+
+	// An unescaped break-out would close the node's quoted string and start
+	// a real URL attribute; escapeDOT must keep the embedded quotes backslashed.
+	if strings.Contains(dot, `URL="javascript:alert(1)"`) {
+		t.Errorf("GenerateDOT emitted an unescaped injected URL attribute:\n%s", dot)
+	}
+
+	wantNode := `"` + escapeDOT(malicious) + `"`
+	if !strings.Contains(dot, wantNode) {
+		t.Errorf("expected GenerateDOT to emit the status name as a single escaped quoted string, got:\n%s", dot)
+	}
+}